@@ -0,0 +1,70 @@
+package core
+
+import "time"
+
+// ServerDesc describes a server as observed by a single heartbeat. It is
+// immutable once published to a ServerMonitor's subscribers.
+type ServerDesc struct {
+	endpoint          Endpoint
+	canonicalEndpoint Endpoint
+
+	electionID         ObjectID
+	lastWriteTimestamp time.Time
+	maxBatchCount      int64
+	maxDocumentSize    int64
+	maxMessageSize     int64
+	members            []Endpoint
+	serverType         ServerType
+	setName            string
+	setVersion         int64
+	tags               map[string]string
+	wireVersion        Range
+	version            Version
+	lastError          error
+
+	averageRTT time.Duration
+
+	// minRTT and rttHistogram back MinRTT and RTTQuantile. They're
+	// populated from the monitor's rolling window and histogram at the
+	// end of a successful heartbeat; see (*ServerMonitor).heartbeat.
+	minRTT       time.Duration
+	rttHistogram *RTTHistogram
+}
+
+// setAverageRTT sets the exponentially-weighted moving average RTT
+// computed for this heartbeat.
+func (desc *ServerDesc) setAverageRTT(rtt time.Duration) {
+	desc.averageRTT = rtt
+}
+
+// AverageRTT returns the exponentially-weighted moving average RTT as of
+// this description.
+func (desc *ServerDesc) AverageRTT() time.Duration {
+	return desc.averageRTT
+}
+
+// setMinRTT sets the minimum of the rolling window of RTT samples as of
+// this description.
+func (desc *ServerDesc) setMinRTT(rtt time.Duration) {
+	desc.minRTT = rtt
+}
+
+// MinRTT returns the minimum of the rolling window of RTT samples as of
+// this description. It's what the latency-window server-selection
+// algorithm uses to find servers within localThresholdMS of the fastest
+// one, which AverageRTT is too lossy to support.
+func (desc *ServerDesc) MinRTT() time.Duration {
+	return desc.minRTT
+}
+
+// setRTTHistogram sets the RTT histogram snapshot backing RTTQuantile.
+func (desc *ServerDesc) setRTTHistogram(h *RTTHistogram) {
+	desc.rttHistogram = h
+}
+
+// RTTQuantile returns an approximation of the q-th quantile (e.g. 0.95
+// for p95) of the RTT samples recorded as of this description. It
+// returns 0 if no samples have been recorded yet.
+func (desc *ServerDesc) RTTQuantile(q float64) time.Duration {
+	return desc.rttHistogram.Quantile(q)
+}
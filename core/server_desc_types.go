@@ -0,0 +1,105 @@
+package core
+
+import (
+	"time"
+
+	"github.com/10gen/mongo-go-driver/bson"
+)
+
+// Endpoint identifies a server by its host:port address.
+type Endpoint string
+
+// Range describes an inclusive [Min, Max] bound, e.g. of wire versions.
+type Range struct {
+	Min int32
+	Max int32
+}
+
+// ServerType classifies a server's role within its topology, as derived
+// from its isMaster reply.
+type ServerType uint32
+
+// The possible values of ServerType.
+const (
+	UnknownServerType ServerType = iota
+	Standalone
+	RSPrimary
+	RSSecondary
+	Mongos
+)
+
+// ObjectID is a MongoDB ObjectId, e.g. a replica set electionId.
+type ObjectID [12]byte
+
+// Version is a server version parsed from a buildInfo reply.
+type Version struct {
+	Desc  string
+	Parts []int64
+}
+
+// NewVersionWithDesc returns a Version with the given display string and
+// dotted-integer parts.
+func NewVersionWithDesc(desc string, parts ...int64) Version {
+	return Version{Desc: desc, Parts: parts}
+}
+
+// ConnectionOptions configures a connection dialed for a server
+// heartbeat.
+type ConnectionOptions struct {
+	Endpoint Endpoint
+}
+
+// ConnectionCloser is the surface the monitor needs from a heartbeat
+// connection: an identity for event correlation, and the ability to be
+// torn down to unblock an in-flight read.
+type ConnectionCloser interface {
+	ID() string
+	Close() error
+}
+
+// isMasterResult is the decoded reply to an isMaster call.
+type isMasterResult struct {
+	OK                  bool
+	Me                  string
+	ElectionID          ObjectID
+	LastWriteTimestamp  time.Time
+	MaxWriteBatchSize   int64
+	MaxBSONObjectSize   int64
+	MaxMessageSizeBytes int64
+	SetName             string
+	SetVersion          int64
+	Tags                map[string]string
+	MinWireVersion      int32
+	MaxWireVersion      int32
+	Hosts               []string
+	Type                ServerType
+	topologyVersion     *TopologyVersion
+
+	raw bson.Reader
+}
+
+// Members returns the replica set members listed in the reply.
+func (r *isMasterResult) Members() []Endpoint {
+	members := make([]Endpoint, len(r.Hosts))
+	for i, h := range r.Hosts {
+		members[i] = Endpoint(h)
+	}
+	return members
+}
+
+// ServerType returns the server's role as classified from the reply.
+func (r *isMasterResult) ServerType() ServerType {
+	return r.Type
+}
+
+// TopologyVersion returns the reply's topologyVersion field, or nil if
+// the server didn't include one.
+func (r *isMasterResult) TopologyVersion() *TopologyVersion {
+	return r.topologyVersion
+}
+
+// buildInfoResult is the decoded reply to a buildInfo call.
+type buildInfoResult struct {
+	Version      string
+	VersionArray []int64
+}
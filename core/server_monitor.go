@@ -3,6 +3,7 @@ package core
 //go:generate go run spec_rtt_internal_test_generator.go
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -13,47 +14,88 @@ import (
 // StartServerMonitor returns a new ServerMonitor containing a channel
 // that will send a ServerDesc everytime it is updated.
 func StartServerMonitor(opts ServerOptions) (*ServerMonitor, error) {
+	return newServerMonitor(opts, DialConnection, describeServer)
+}
+
+// newServerMonitor does the real work behind StartServerMonitor, taking
+// the dial/describe calls as parameters so tests can fake the network
+// without touching it.
+func newServerMonitor(opts ServerOptions, dial dialFunc, describe describeFunc) (*ServerMonitor, error) {
 	if err := opts.validate(); err != nil {
 		return nil, err
 	}
 
 	opts.fillDefaults()
 
-	done := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
 	m := &ServerMonitor{
-		subscribers:    make(map[int]chan *ServerDesc),
-		done:           done,
-		connectionOpts: opts.ConnectionOptions,
+		subscribers:          make(map[int]chan *ServerDesc),
+		checkNow:             make(chan struct{}, 1),
+		ctx:                  ctx,
+		cancel:               cancel,
+		connectionOpts:       opts.ConnectionOptions,
+		monitorConfig:        opts.ServerMonitorConfig,
+		heartbeatInterval:    opts.HeartbeatInterval,
+		reconnectWait:        opts.ReconnectWait,
+		reconnectJitter:      opts.ReconnectJitter,
+		maxReconnectAttempts: opts.MaxReconnectAttempts,
+		dial:                 dial,
+		describe:             describe,
 	}
 
+	// Unblock any in-flight describeServer read as soon as Stop() cancels
+	// the context, rather than waiting for it to return on its own.
+	m.wg.Add(1)
 	go func() {
+		defer m.wg.Done()
+		<-ctx.Done()
+		m.closeConn()
+	}()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		var lastHeartbeat time.Time
+		runHeartbeat := func() {
+			desc := m.heartbeat(ctx)
+			m.descLock.Lock()
+			m.desc = desc
+			m.descLock.Unlock()
+
+			// send the update to all subscribers
+			m.subscriberLock.Lock()
+			for _, ch := range m.subscribers {
+				select {
+				case <-ch:
+					// drain the channel if not empty
+				default:
+					// do nothing if chan already empty
+				}
+				ch <- desc
+			}
+			m.subscriberLock.Unlock()
+
+			lastHeartbeat = time.Now()
+		}
+
 		timer := time.NewTimer(0)
 		for {
 			select {
 			case <-timer.C:
-				// get an updated server description
-				desc := m.heartbeat()
-				m.descLock.Lock()
-				m.desc = desc
-				m.descLock.Unlock()
-
-				// send the update to all subscribers
-				m.subscriberLock.Lock()
-				for _, ch := range m.subscribers {
-					select {
-					case <-ch:
-						// drain the channel if not empty
-					default:
-						// do nothing if chan already empty
-					}
-					ch <- desc
+				runHeartbeat()
+				timer.Stop()
+				timer.Reset(opts.HeartbeatInterval)
+			case <-m.checkNow:
+				// RequestImmediateCheck fires this; ignore it if we just
+				// ran a heartbeat to keep a flurry of callers from
+				// storming the server.
+				if time.Since(lastHeartbeat) < opts.MinHeartbeatInterval {
+					continue
 				}
-				m.subscriberLock.Unlock()
-
-				// restart the heartbeat timer
+				runHeartbeat()
 				timer.Stop()
 				timer.Reset(opts.HeartbeatInterval)
-			case <-done:
+			case <-ctx.Done():
 				timer.Stop()
 				m.subscriberLock.Lock()
 				for id, ch := range m.subscribers {
@@ -61,7 +103,7 @@ func StartServerMonitor(opts ServerOptions) (*ServerMonitor, error) {
 					delete(m.subscribers, id)
 				}
 				m.subscriptionsClosed = true
-				m.subscriberLock.Lock()
+				m.subscriberLock.Unlock()
 				return
 			}
 		}
@@ -77,17 +119,75 @@ type ServerMonitor struct {
 	subscriberLock      sync.Mutex
 
 	conn           ConnectionCloser
+	rttConn        ConnectionCloser
+	connLock       sync.Mutex
 	connectionOpts ConnectionOptions
 	desc           *ServerDesc
 	descLock       sync.Mutex
-	done           chan struct{}
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
 	averageRTT     time.Duration
 	averageRTTSet  bool
+	rttSamples     []time.Duration
+	rttHistogram   *RTTHistogram
+	monitorConfig  ServerMonitorConfig
+
+	// dial and describe issue the connection and isMaster call a
+	// heartbeat needs. They default to DialConnection/describeServer;
+	// tests swap them out to fake the network.
+	dial     dialFunc
+	describe describeFunc
+
+	// state is a ServerState, accessed only via State()/setState().
+	state uint32
+
+	// checkNow backs RequestImmediateCheck. heartbeatInterval bounds the
+	// reconnect backoff computed in heartbeat(); reconnectWait,
+	// reconnectJitter and maxReconnectAttempts configure it.
+	checkNow             chan struct{}
+	heartbeatInterval    time.Duration
+	reconnectWait        time.Duration
+	reconnectJitter      time.Duration
+	maxReconnectAttempts int
+
+	// streaming (awaitable isMaster) state. topologyVersion is only ever
+	// advanced by the main heartbeat loop; rttMonitorOnce guards startup
+	// of the dedicated RTT-sampling goroutine described in sampleRTT.
+	streamingEnabled bool
+	topologyVersion  *TopologyVersion
+	rttMonitorOnce   sync.Once
 }
 
-// Stop turns off the monitor.
+// dialFunc dials a new connection for a heartbeat; it's the shape of
+// DialConnection.
+type dialFunc func(ConnectionOptions) (ConnectionCloser, error)
+
+// describeFunc issues an isMaster (and, the first time, a buildInfo)
+// call against conn; it's the shape of describeServer.
+type describeFunc func(context.Context, ConnectionCloser, *streamOptions) (*isMasterResult, *buildInfoResult, error)
+
+// Stop turns off the monitor. It blocks until the heartbeat goroutine
+// and any subscriber channels it owns are fully torn down, so callers
+// can rely on nothing touching the monitor's state once Stop returns.
 func (m *ServerMonitor) Stop() {
-	close(m.done)
+	m.cancel()
+	m.wg.Wait()
+}
+
+// closeConn closes the active heartbeat and RTT-sampling connections, if
+// any, unblocking a describeServer call that's parked in a socket read.
+func (m *ServerMonitor) closeConn() {
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+	if m.conn != nil {
+		m.conn.Close()
+		m.conn = nil
+	}
+	if m.rttConn != nil {
+		m.rttConn.Close()
+		m.rttConn = nil
+	}
 }
 
 // Subscribe returns a channel on which all updated ServerDescs
@@ -128,41 +228,126 @@ func (m *ServerMonitor) Subscribe() (<-chan *ServerDesc, func(), error) {
 	return ch, unsubscribe, nil
 }
 
-func (m *ServerMonitor) heartbeat() *ServerDesc {
-	const maxRetryCount = 2
+func (m *ServerMonitor) heartbeat(ctx context.Context) *ServerDesc {
 	var savedErr error
 	var desc *ServerDesc
-	for i := 1; i <= maxRetryCount; i++ {
+retryLoop:
+	for attempt := 1; attempt <= m.maxReconnectAttempts; attempt++ {
+		if attempt > 1 {
+			wait := reconnectBackoff(attempt-1, m.reconnectWait, m.heartbeatInterval, m.reconnectJitter)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				savedErr = ctx.Err()
+				break retryLoop
+			}
+		}
+		if ctx.Err() != nil {
+			savedErr = ctx.Err()
+			break retryLoop
+		}
+
+		m.setState(Connecting)
+
+		m.connLock.Lock()
 		if m.conn == nil {
 			// TODO: should this use the connection dialer from
 			// the options? If so, it means authentication happens
 			// for heartbeat connections as well, which makes
 			// sharing a monitor in a multi-tenant arrangement
 			// impossible.
-			conn, err := DialConnection(m.connectionOpts)
+			conn, err := m.dial(m.connectionOpts)
 			if err != nil {
+				m.connLock.Unlock()
 				savedErr = err
 				if conn != nil {
 					conn.Close()
 				}
 				m.conn = nil
+				m.setState(Disconnected)
 				continue
 			}
 			m.conn = conn
 		}
+		conn := m.conn
+		m.connLock.Unlock()
+
+		connID := conn.ID()
+		awaited := m.streamingEnabled
+		m.publishEvent(ServerHeartbeatStartedEvent{ConnectionID: connID, Awaited: awaited})
+
+		var streamOpts *streamOptions
+		if awaited {
+			streamOpts = &streamOptions{
+				maxAwaitTimeMS:  int64(m.heartbeatInterval / time.Millisecond),
+				topologyVersion: m.topologyVersion,
+			}
+		}
 
 		now := time.Now()
-		isMasterResult, buildInfoResult, err := describeServer(m.conn)
+		isMasterResult, buildInfoResult, err := m.describe(ctx, conn, streamOpts)
+		delay := time.Since(now)
 		if err != nil {
 			savedErr = err
-			m.conn.Close()
-			m.conn = nil
+			m.publishEvent(ServerHeartbeatFailedEvent{
+				DurationNanos: delay.Nanoseconds(),
+				Failure:       err,
+				ConnectionID:  connID,
+				Awaited:       awaited,
+			})
+			m.connLock.Lock()
+			conn.Close()
+			if m.conn == conn {
+				m.conn = nil
+			}
+			m.connLock.Unlock()
+			m.streamingEnabled = false
+			m.setState(Disconnected)
 			continue
 		}
-		delay := time.Since(now)
+
+		m.setState(Connected)
+
+		// RTT samples never come from awaited replies: maxAwaitTimeMS
+		// inflates their latency. Use the dedicated RTT connection's
+		// stats instead.
+		var avgRTT, minRTT time.Duration
+		if awaited {
+			avgRTT, minRTT = m.rttStats()
+		} else {
+			m.descLock.Lock()
+			avgRTT = m.updateAverageRTT(delay)
+			minRTT = m.minRTT()
+			m.descLock.Unlock()
+		}
+
+		m.publishEvent(ServerHeartbeatSucceededEvent{
+			DurationNanos: delay.Nanoseconds(),
+			Reply:         isMasterResult.raw,
+			ConnectionID:  connID,
+			Awaited:       awaited,
+			MinRTTNanos:   minRTT.Nanoseconds(),
+		})
+
+		// A successful reply is not a failure, whether or not it
+		// advanced topologyVersion: an awaited isMaster that times out
+		// with an unchanged topologyVersion is the normal steady state
+		// of a healthy, unchanging replica set, not a stale push to
+		// discard. Build a ServerDesc from it and stop retrying; only
+		// the dial and describeServer error cases above should consume
+		// the reconnect backoff/attempt budget.
+		newVersion := isMasterResult.TopologyVersion()
+		m.topologyVersion = newVersion
+		if newVersion != nil && !m.streamingEnabled {
+			m.streamingEnabled = true
+			m.ensureRTTMonitor()
+		}
 
 		desc = buildServerDesc(m.connectionOpts.Endpoint, isMasterResult, buildInfoResult)
-		desc.setAverageRTT(m.updateAverageRTT(delay))
+		desc.setAverageRTT(avgRTT)
+		desc.setMinRTT(minRTT)
+		desc.setRTTHistogram(m.rttHistogramSnapshot())
+		break retryLoop
 	}
 
 	if desc == nil {
@@ -175,8 +360,10 @@ func (m *ServerMonitor) heartbeat() *ServerDesc {
 	return desc
 }
 
-// updateAverageRTT calcuates the averageRTT of the server
-// given its most recent RTT value
+// updateAverageRTT calcuates the averageRTT of the server given its most
+// recent RTT value, and feeds delay into the rolling window and
+// histogram that back MinRTT and RTTQuantile. Callers must hold
+// descLock.
 func (m *ServerMonitor) updateAverageRTT(delay time.Duration) time.Duration {
 	if !m.averageRTTSet {
 		m.averageRTT = delay
@@ -184,6 +371,17 @@ func (m *ServerMonitor) updateAverageRTT(delay time.Duration) time.Duration {
 		alpha := 0.2
 		m.averageRTT = time.Duration(alpha*float64(delay) + (1-alpha)*float64(m.averageRTT))
 	}
+	m.averageRTTSet = true
+
+	m.rttSamples = append(m.rttSamples, delay)
+	if len(m.rttSamples) > rttWindowSize {
+		m.rttSamples = m.rttSamples[len(m.rttSamples)-rttWindowSize:]
+	}
+	if m.rttHistogram == nil {
+		m.rttHistogram = NewRTTHistogram()
+	}
+	m.rttHistogram.Add(delay)
+
 	return m.averageRTT
 }
 
@@ -218,4 +416,4 @@ func buildServerDesc(endpoint Endpoint, isMasterResult *isMasterResult, buildInf
 	}
 
 	return desc
-}
\ No newline at end of file
+}
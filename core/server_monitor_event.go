@@ -0,0 +1,69 @@
+package core
+
+import "github.com/10gen/mongo-go-driver/bson"
+
+// ServerMonitorEvent is implemented by every event that a ServerMonitor
+// publishes over the course of its heartbeat loop. Consumers type-switch
+// on the concrete type to pull out event-specific fields, mirroring the
+// event surface described by the SDAM monitoring spec.
+type ServerMonitorEvent interface {
+	serverMonitorEvent()
+}
+
+// ServerHeartbeatStartedEvent is published immediately before the
+// ServerMonitor issues an isMaster call against ConnectionID.
+type ServerHeartbeatStartedEvent struct {
+	ConnectionID string
+	Awaited      bool
+}
+
+func (ServerHeartbeatStartedEvent) serverMonitorEvent() {}
+
+// ServerHeartbeatSucceededEvent is published after an isMaster call on
+// ConnectionID completes successfully. DurationNanos is measured from
+// immediately before the call was issued.
+type ServerHeartbeatSucceededEvent struct {
+	DurationNanos int64
+	Reply         bson.Reader
+	ConnectionID  string
+	Awaited       bool
+	MinRTTNanos   int64
+}
+
+func (ServerHeartbeatSucceededEvent) serverMonitorEvent() {}
+
+// ServerHeartbeatFailedEvent is published after an isMaster call on
+// ConnectionID fails. DurationNanos is measured from immediately before
+// the call was issued.
+type ServerHeartbeatFailedEvent struct {
+	DurationNanos int64
+	Failure       error
+	ConnectionID  string
+	Awaited       bool
+}
+
+func (ServerHeartbeatFailedEvent) serverMonitorEvent() {}
+
+// ServerStateChangedEvent is published whenever a ServerMonitor's State()
+// transitions, e.g. when a reconnect attempt succeeds after an outage.
+type ServerStateChangedEvent struct {
+	From ServerState
+	To   ServerState
+}
+
+func (ServerStateChangedEvent) serverMonitorEvent() {}
+
+// ServerMonitorConfig configures the event-listener subsystem for a
+// ServerMonitor. It is set via ServerOptions.ServerMonitorConfig.
+type ServerMonitorConfig struct {
+	// Callback, when non-nil, is invoked synchronously on the monitor's
+	// own goroutine for every ServerMonitorEvent it publishes.
+	Callback func(ServerMonitorEvent)
+}
+
+// publishEvent invokes the configured callback, if any, with evt.
+func (m *ServerMonitor) publishEvent(evt ServerMonitorEvent) {
+	if m.monitorConfig.Callback != nil {
+		m.monitorConfig.Callback(evt)
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// TopologyVersion mirrors the topologyVersion field of an isMaster reply.
+// It lets a streaming (awaited) heartbeat recognize whether a reply
+// actually advances the server's state or is a stale duplicate.
+type TopologyVersion struct {
+	ProcessID string
+	Counter   int64
+}
+
+// newerThan reports whether tv is strictly newer than other. A nil
+// receiver or argument is treated as "no topologyVersion yet", so any
+// concrete version is newer than nil, and nil is never newer than
+// anything.
+func (tv *TopologyVersion) newerThan(other *TopologyVersion) bool {
+	if tv == nil {
+		return false
+	}
+	if other == nil {
+		return true
+	}
+	return tv.ProcessID == other.ProcessID && tv.Counter > other.Counter
+}
+
+// streamOptions carries the extra parameters sent with an awaited
+// (streaming) isMaster so the server can push state changes as soon as
+// they occur instead of waiting for the next poll.
+type streamOptions struct {
+	maxAwaitTimeMS  int64
+	topologyVersion *TopologyVersion
+}
+
+// rttMonitorInterval is how often the dedicated RTT connection polls
+// with a classic (non-awaited) isMaster once streaming has first
+// engaged. Polling goes on until the monitor is stopped, even after a
+// failed heartbeat switches streaming off.
+// Awaited replies are never used to compute averageRTT: maxAwaitTimeMS
+// inflates their latency and would make the average useless.
+const rttMonitorInterval = 10 * time.Second
+
+// ensureRTTMonitor starts the dedicated RTT-sampling goroutine the first
+// time streaming engages. It is a no-op on subsequent calls.
+func (m *ServerMonitor) ensureRTTMonitor() {
+	m.rttMonitorOnce.Do(func() {
+		m.wg.Add(1)
+		go m.rttMonitorLoop(m.ctx)
+	})
+}
+
+func (m *ServerMonitor) rttMonitorLoop(ctx context.Context) {
+	defer m.wg.Done()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			m.sampleRTT(ctx)
+			timer.Reset(rttMonitorInterval)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sampleRTT dials a short-lived connection and runs a classic isMaster
+// on it purely to keep averageRTT accurate while the main connection is
+// blocked on an awaited reply. The connection is registered as
+// m.rttConn so closeConn() can tear it down on cancel just like the
+// main heartbeat connection, rather than leaving it to block Stop()
+// forever on an in-flight read.
+func (m *ServerMonitor) sampleRTT(ctx context.Context) {
+	conn, err := m.dial(m.connectionOpts)
+	if err != nil {
+		return
+	}
+
+	m.connLock.Lock()
+	if ctx.Err() != nil {
+		m.connLock.Unlock()
+		conn.Close()
+		return
+	}
+	m.rttConn = conn
+	m.connLock.Unlock()
+
+	defer func() {
+		m.connLock.Lock()
+		if m.rttConn == conn {
+			m.rttConn = nil
+		}
+		m.connLock.Unlock()
+		conn.Close()
+	}()
+
+	now := time.Now()
+	if _, _, err := m.describe(ctx, conn, nil); err != nil {
+		return
+	}
+	delay := time.Since(now)
+
+	m.descLock.Lock()
+	m.updateAverageRTT(delay)
+	m.descLock.Unlock()
+}
+
+// rttStats returns the most recently computed averageRTT and MinRTT.
+func (m *ServerMonitor) rttStats() (avgRTT, minRTT time.Duration) {
+	m.descLock.Lock()
+	defer m.descLock.Unlock()
+	return m.averageRTT, m.minRTT()
+}
@@ -0,0 +1,63 @@
+package core
+
+import "testing"
+
+func TestTopologyVersionNewerThan(t *testing.T) {
+	tests := []struct {
+		name  string
+		tv    *TopologyVersion
+		other *TopologyVersion
+		want  bool
+	}{
+		{
+			name:  "nil receiver is never newer",
+			tv:    nil,
+			other: &TopologyVersion{ProcessID: "a", Counter: 1},
+			want:  false,
+		},
+		{
+			name:  "any concrete version is newer than nil",
+			tv:    &TopologyVersion{ProcessID: "a", Counter: 0},
+			other: nil,
+			want:  true,
+		},
+		{
+			name:  "both nil is not newer",
+			tv:    nil,
+			other: nil,
+			want:  false,
+		},
+		{
+			name:  "higher counter on the same process is newer",
+			tv:    &TopologyVersion{ProcessID: "a", Counter: 2},
+			other: &TopologyVersion{ProcessID: "a", Counter: 1},
+			want:  true,
+		},
+		{
+			name:  "lower counter on the same process is not newer",
+			tv:    &TopologyVersion{ProcessID: "a", Counter: 1},
+			other: &TopologyVersion{ProcessID: "a", Counter: 2},
+			want:  false,
+		},
+		{
+			name:  "equal counter on the same process is not newer",
+			tv:    &TopologyVersion{ProcessID: "a", Counter: 1},
+			other: &TopologyVersion{ProcessID: "a", Counter: 1},
+			want:  false,
+		},
+		{
+			name:  "a higher counter from a different process is not newer",
+			tv:    &TopologyVersion{ProcessID: "b", Counter: 5},
+			other: &TopologyVersion{ProcessID: "a", Counter: 1},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tv.newerThan(tt.other); got != tt.want {
+				t.Errorf("newerThan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeConn struct {
+	id string
+}
+
+func (c *fakeConn) ID() string   { return c.id }
+func (c *fakeConn) Close() error { return nil }
+
+// TestHeartbeatStreamingNonAdvancingReplyStaysPopulated drives
+// StartServerMonitor end to end against a faked connection and
+// describeServer that always succeeds but never advances
+// topologyVersion once streaming engages -- the normal steady state of
+// a healthy, unchanging replica set. Published ServerDescs must keep
+// reflecting the real reply instead of falling back to the zero-valued
+// "no description" case, and Stop() must still return promptly.
+func TestHeartbeatStreamingNonAdvancingReplyStaysPopulated(t *testing.T) {
+	tv := &TopologyVersion{ProcessID: "p-1", Counter: 7}
+
+	var describeCalls int32
+	dial := func(ConnectionOptions) (ConnectionCloser, error) {
+		return &fakeConn{id: "conn-1"}, nil
+	}
+	describe := func(ctx context.Context, conn ConnectionCloser, opts *streamOptions) (*isMasterResult, *buildInfoResult, error) {
+		atomic.AddInt32(&describeCalls, 1)
+		result := &isMasterResult{
+			OK:              true,
+			Me:              "host:27017",
+			SetName:         "rs0",
+			Hosts:           []string{"host:27017"},
+			Type:            RSPrimary,
+			MaxWireVersion:  9,
+			topologyVersion: tv,
+		}
+		return result, &buildInfoResult{Version: "4.4.0"}, nil
+	}
+
+	opts := ServerOptions{
+		ConnectionOptions: ConnectionOptions{Endpoint: "host:27017"},
+		HeartbeatInterval: 10 * time.Millisecond,
+	}
+
+	m, err := newServerMonitor(opts, dial, describe)
+	if err != nil {
+		t.Fatalf("newServerMonitor() error = %v", err)
+	}
+
+	ch, unsubscribe, err := m.Subscribe()
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	const wantHeartbeats = 3
+	seen := 0
+	deadline := time.After(2 * time.Second)
+	for seen < wantHeartbeats {
+		select {
+		case desc := <-ch:
+			if desc == nil {
+				continue
+			}
+			if desc.lastError != nil {
+				t.Fatalf("heartbeat %d published lastError = %v, want nil", seen, desc.lastError)
+			}
+			if desc.setName != "rs0" {
+				t.Fatalf("heartbeat %d published setName = %q, want %q (a non-advancing streaming reply was wrongly discarded)", seen, desc.setName, "rs0")
+			}
+			seen++
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d heartbeats, only saw %d", wantHeartbeats, seen)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&describeCalls); calls < wantHeartbeats {
+		t.Fatalf("describeServer called %d times, want at least %d (streaming should keep polling instead of stalling on the non-advancing reply)", calls, wantHeartbeats)
+	}
+
+	// Stop closes every remaining subscription channel itself, so the
+	// subscription is released before Stop rather than after it.
+	unsubscribe()
+
+	stopped := make(chan struct{})
+	go func() {
+		m.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop() did not return promptly")
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import "time"
+
+// ServerOptions configures a ServerMonitor started via StartServerMonitor.
+type ServerOptions struct {
+	// ConnectionOptions configures the connections the monitor dials for
+	// its heartbeats.
+	ConnectionOptions ConnectionOptions
+
+	// HeartbeatInterval is how often the monitor polls the server with
+	// an isMaster call.
+	HeartbeatInterval time.Duration
+
+	// ServerMonitorConfig configures the event-listener subsystem the
+	// monitor publishes ServerMonitorEvents through; see
+	// ServerMonitorConfig for details.
+	ServerMonitorConfig ServerMonitorConfig
+
+	// ReconnectWait is the base wait before the first reconnect attempt
+	// after a failed heartbeat; reconnectBackoff doubles it on each
+	// subsequent attempt, capped at HeartbeatInterval.
+	ReconnectWait time.Duration
+
+	// ReconnectJitter randomizes reconnectBackoff's wait by +/- this
+	// much, so that multiple monitors recovering from the same outage
+	// don't all retry in lockstep.
+	ReconnectJitter time.Duration
+
+	// MaxReconnectAttempts bounds how many attempts, the first one
+	// included, heartbeat() makes before giving up and returning a
+	// ServerDesc carrying the last error.
+	MaxReconnectAttempts int
+
+	// MinHeartbeatInterval rate-limits RequestImmediateCheck: a check is
+	// ignored if one already ran more recently than this.
+	MinHeartbeatInterval time.Duration
+}
+
+// validate returns an error if opts is not usable by StartServerMonitor.
+func (opts *ServerOptions) validate() error {
+	return nil
+}
+
+// fillDefaults fills in the zero-valued fields of opts with their
+// defaults.
+func (opts *ServerOptions) fillDefaults() {
+	if opts.HeartbeatInterval == 0 {
+		opts.HeartbeatInterval = 10 * time.Second
+	}
+	if opts.ReconnectWait == 0 {
+		opts.ReconnectWait = 500 * time.Millisecond
+	}
+	if opts.ReconnectJitter == 0 {
+		opts.ReconnectJitter = 100 * time.Millisecond
+	}
+	if opts.MaxReconnectAttempts == 0 {
+		opts.MaxReconnectAttempts = 3
+	}
+	if opts.MinHeartbeatInterval == 0 {
+		opts.MinHeartbeatInterval = 500 * time.Millisecond
+	}
+}
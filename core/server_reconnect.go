@@ -0,0 +1,40 @@
+package core
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RequestImmediateCheck asks the monitor to run a heartbeat right away
+// instead of waiting for the next tick. This is the hook a connection
+// pool uses to force rediscovery as soon as it sees a network error
+// mid-operation. It is rate-limited by MinHeartbeatInterval so a caller
+// can't storm the server with checks.
+func (m *ServerMonitor) RequestImmediateCheck() {
+	select {
+	case m.checkNow <- struct{}{}:
+	default:
+		// a check is already pending; no need to queue another
+	}
+}
+
+// reconnectBackoff computes the delay before reconnect attempt n
+// (1-based): wait = min(base*2^(n-1), cap), randomized by +/- jitter of
+// uniform noise. Borrowed from the NATS-style reconnect strategy.
+func reconnectBackoff(n int, base, cap, jitter time.Duration) time.Duration {
+	wait := cap
+	if shift := uint(n - 1); shift < 32 {
+		if scaled := base << shift; scaled > 0 && scaled < cap {
+			wait = scaled
+		}
+	}
+
+	if jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(2*jitter))) - jitter
+		if wait < 0 {
+			wait = 0
+		}
+	}
+
+	return wait
+}
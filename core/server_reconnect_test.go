@@ -0,0 +1,47 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectBackoff(t *testing.T) {
+	const base = 100 * time.Millisecond
+	const capDuration = 800 * time.Millisecond
+
+	tests := []struct {
+		name string
+		n    int
+		want time.Duration
+	}{
+		{name: "first attempt waits the base", n: 1, want: base},
+		{name: "second attempt doubles", n: 2, want: 2 * base},
+		{name: "third attempt doubles again", n: 3, want: 4 * base},
+		{name: "growth is capped", n: 4, want: capDuration},
+		{name: "growth stays capped", n: 10, want: capDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reconnectBackoff(tt.n, base, capDuration, 0); got != tt.want {
+				t.Errorf("reconnectBackoff(%d, ...) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconnectBackoffJitter(t *testing.T) {
+	const base = 100 * time.Millisecond
+	const capDuration = 800 * time.Millisecond
+	const jitter = 20 * time.Millisecond
+
+	for i := 0; i < 50; i++ {
+		wait := reconnectBackoff(2, base, capDuration, jitter)
+		if wait < 0 {
+			t.Fatalf("reconnectBackoff returned a negative duration: %v", wait)
+		}
+		if lo, hi := 2*base-jitter, 2*base+jitter; wait < lo || wait > hi {
+			t.Fatalf("reconnectBackoff = %v, want within [%v, %v]", wait, lo, hi)
+		}
+	}
+}
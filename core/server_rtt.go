@@ -0,0 +1,118 @@
+package core
+
+import "time"
+
+// rttWindowSize is how many recent RTT samples MinRTT is computed over:
+// enough to smooth out a one-off spike without reacting too slowly to a
+// server that has genuinely gotten faster or slower.
+const rttWindowSize = 10
+
+// rttHistogramBuckets are the upper (exclusive) bounds of an
+// RTTHistogram's buckets, doubling from 1ms up to ~30s (the last bound
+// is 32.768s, the first doubling past 30s). Samples at or above the
+// last bound fall into a final overflow bucket.
+var rttHistogramBuckets = buildRTTHistogramBuckets()
+
+func buildRTTHistogramBuckets() []time.Duration {
+	var buckets []time.Duration
+	for d := time.Millisecond; d/2 < 30*time.Second; d *= 2 {
+		buckets = append(buckets, d)
+	}
+	return buckets
+}
+
+// RTTHistogram is a bounded exponential-bucket histogram of RTT samples.
+// It supports cheap p50/p95/p99-style quantile queries without retaining
+// every sample, at the cost of bucket-granularity precision.
+type RTTHistogram struct {
+	counts []uint64
+	total  uint64
+}
+
+// NewRTTHistogram returns an empty RTTHistogram.
+func NewRTTHistogram() *RTTHistogram {
+	return &RTTHistogram{counts: make([]uint64, len(rttHistogramBuckets)+1)}
+}
+
+// Add records a single RTT sample.
+func (h *RTTHistogram) Add(d time.Duration) {
+	for i, bound := range rttHistogramBuckets {
+		if d < bound {
+			h.counts[i]++
+			h.total++
+			return
+		}
+	}
+	h.counts[len(h.counts)-1]++
+	h.total++
+}
+
+// Quantile returns an approximation of the q-th quantile (e.g. 0.95 for
+// p95) of the recorded samples, using each matching bucket's upper bound
+// as the estimate. It returns 0 if no samples have been recorded.
+func (h *RTTHistogram) Quantile(q float64) time.Duration {
+	if h == nil || h.total == 0 {
+		return 0
+	}
+
+	// rank is the 0-indexed position of the sample we want among the
+	// total, sorted ascending. Using q*total directly as the cumulative
+	// threshold is off by one at q == 1.0: it equals total, which no
+	// bucket's cumulative count can ever exceed, so the query always
+	// falls through to the overflow bucket regardless of where the
+	// samples actually landed. Clamping to total-1 asks for the last
+	// (i.e. highest) sample instead.
+	rank := uint64(q * float64(h.total))
+	if rank >= h.total {
+		rank = h.total - 1
+	}
+
+	var cumulative uint64
+	for i, count := range h.counts {
+		cumulative += count
+		if cumulative > rank {
+			if i == len(rttHistogramBuckets) {
+				return rttHistogramBuckets[len(rttHistogramBuckets)-1] * 2
+			}
+			return rttHistogramBuckets[i]
+		}
+	}
+	return rttHistogramBuckets[len(rttHistogramBuckets)-1] * 2
+}
+
+// minRTT returns the minimum of the current rolling window of RTT
+// samples. Callers must hold descLock. Used by the latency-window
+// server-selection algorithm to pick servers within localThresholdMS of
+// the fastest one, which the smoothed average is too lossy to support.
+func (m *ServerMonitor) minRTT() time.Duration {
+	if len(m.rttSamples) == 0 {
+		return 0
+	}
+	min := m.rttSamples[0]
+	for _, d := range m.rttSamples[1:] {
+		if d < min {
+			min = d
+		}
+	}
+	return min
+}
+
+// rttHistogramSnapshot returns a copy of the histogram accumulated so
+// far, safe for a reader to hold onto after this call returns. The live
+// m.rttHistogram keeps being mutated by later heartbeats under
+// descLock, so handing out the pointer itself would let a published,
+// supposedly-immutable ServerDesc's histogram change underneath its
+// readers.
+func (m *ServerMonitor) rttHistogramSnapshot() *RTTHistogram {
+	m.descLock.Lock()
+	defer m.descLock.Unlock()
+	if m.rttHistogram == nil {
+		return nil
+	}
+	snapshot := &RTTHistogram{
+		counts: make([]uint64, len(m.rttHistogram.counts)),
+		total:  m.rttHistogram.total,
+	}
+	copy(snapshot.counts, m.rttHistogram.counts)
+	return snapshot
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRTTHistogramAdd(t *testing.T) {
+	h := NewRTTHistogram()
+	if got := h.Quantile(0.5); got != 0 {
+		t.Fatalf("Quantile on empty histogram = %v, want 0", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		h.Add(500 * time.Microsecond)
+	}
+	if h.total != 10 {
+		t.Fatalf("total = %d, want 10", h.total)
+	}
+	if h.counts[0] != 10 {
+		t.Fatalf("counts[0] = %d, want 10", h.counts[0])
+	}
+}
+
+func TestRTTHistogramQuantile(t *testing.T) {
+	h := NewRTTHistogram()
+	for i := 0; i < 10; i++ {
+		h.Add(500 * time.Microsecond)
+	}
+
+	// All ten samples land well under the first bucket bound (1ms), so
+	// every quantile -- including the max -- should report that bound,
+	// not fall through to the overflow bucket.
+	for _, q := range []float64{0.5, 0.95, 0.99, 1.0} {
+		if got := h.Quantile(q); got != time.Millisecond {
+			t.Errorf("Quantile(%v) = %v, want %v", q, got, time.Millisecond)
+		}
+	}
+}
+
+func TestRTTHistogramQuantileOverflow(t *testing.T) {
+	h := NewRTTHistogram()
+	h.Add(time.Minute)
+
+	want := rttHistogramBuckets[len(rttHistogramBuckets)-1] * 2
+	if got := h.Quantile(1.0); got != want {
+		t.Errorf("Quantile(1.0) = %v, want %v", got, want)
+	}
+}
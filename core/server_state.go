@@ -0,0 +1,41 @@
+package core
+
+import "sync/atomic"
+
+// ServerState describes a ServerMonitor's position in its connection
+// lifecycle.
+type ServerState uint32
+
+// The possible values of ServerState.
+const (
+	Disconnected ServerState = iota
+	Connecting
+	Connected
+)
+
+func (s ServerState) String() string {
+	switch s {
+	case Connecting:
+		return "Connecting"
+	case Connected:
+		return "Connected"
+	default:
+		return "Disconnected"
+	}
+}
+
+// State returns the monitor's current connection state. It is safe to
+// call from any goroutine.
+func (m *ServerMonitor) State() ServerState {
+	return ServerState(atomic.LoadUint32(&m.state))
+}
+
+// setState atomically updates the monitor's state and, if it actually
+// changed, publishes a ServerStateChangedEvent so applications can react
+// to reconnects and outages as they happen.
+func (m *ServerMonitor) setState(s ServerState) {
+	old := ServerState(atomic.SwapUint32(&m.state, uint32(s)))
+	if old != s {
+		m.publishEvent(ServerStateChangedEvent{From: old, To: s})
+	}
+}